@@ -2,16 +2,27 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/credentials/ec2rolecreds"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/feature/ec2/imds"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/rds"
+	rdstypes "github.com/aws/aws-sdk-go-v2/service/rds/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/crossplane/function-sdk-go/errors"
 	"github.com/crossplane/function-sdk-go/logging"
 	fnv1 "github.com/crossplane/function-sdk-go/proto/v1"
@@ -22,6 +33,53 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
+// defaultAssumeRoleSessionName is used when an AssumeRoleConfig doesn't
+// specify its own SessionName.
+const defaultAssumeRoleSessionName = "function-rds-metrics"
+
+// configCache holds resolved aws.Config values keyed by region and, when
+// set, the role that was assumed, so that every Reconcile doesn't re-run
+// the credential provider chain.
+var (
+	configCacheMu sync.Mutex
+	configCache   = make(map[configCacheKey]aws.Config)
+)
+
+// configCacheKey identifies a cached aws.Config. It must capture every
+// input that can change the credentials resolveBaseConfig/getAWSConfig
+// produce, since a single function pod serves many XRs (and therefore
+// many aws-creds secrets, profiles and CredentialsMode values) against
+// the same region concurrently: colliding two of them on the same key
+// would hand one tenant's resolved credentials to another.
+type configCacheKey struct {
+	region      string
+	mode        v1beta1.CredentialsMode
+	profile     string
+	credsDigest string
+	roleARN     string
+}
+
+// credentialsDigest returns a stable, order-independent fingerprint of
+// awsCreds suitable for use in a configCacheKey. It hashes the contents
+// rather than keying on them directly so credential material doesn't
+// linger in the cache's keys.
+func credentialsDigest(awsCreds map[string]string) string {
+	keys := make([]string, 0, len(awsCreds))
+	for k := range awsCreds {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, k := range keys {
+		h.Write([]byte(k))
+		h.Write([]byte{0})
+		h.Write([]byte(awsCreds[k]))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // Function returns RDS metrics from AWS CloudWatch.
 type Function struct {
 	fnv1.UnimplementedFunctionRunnerServiceServer
@@ -29,12 +87,32 @@ type Function struct {
 	log logging.Logger
 }
 
-// RDSMetrics represents the metrics data structure
-type RDSMetrics struct {
-	DatabaseName string                 `json:"databaseName"`
-	Region       string                 `json:"region"`
-	Timestamp    time.Time              `json:"timestamp"`
-	Metrics      map[string]MetricValue `json:"metrics"`
+// CloudWatchMetrics represents the metrics (and, for RDS, alarms) result
+// for a single CloudWatch namespace/dimension set.
+type CloudWatchMetrics struct {
+	Namespace  string                 `json:"namespace"`
+	Dimensions []v1beta1.Dimension    `json:"dimensions"`
+	Region     string                 `json:"region"`
+	Timestamp  time.Time              `json:"timestamp"`
+	Metrics    map[string]MetricValue `json:"metrics"`
+	// Alarms reports the reconciled state of the CloudWatch alarms
+	// declared in v1beta1.Input.Alarms, keyed by alarm name. Omitted
+	// entirely when no alarms are declared.
+	Alarms map[string]AlarmStatus `json:"alarms,omitempty"`
+}
+
+// AlarmStatus reports the reconciled state of a single CloudWatch alarm.
+type AlarmStatus struct {
+	State   string              `json:"state"`
+	Reason  string              `json:"reason,omitempty"`
+	History []AlarmHistoryEvent `json:"history,omitempty"`
+}
+
+// AlarmHistoryEvent is a single alarm state transition surfaced from
+// DescribeAlarmHistory.
+type AlarmHistoryEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Summary   string    `json:"summary,omitempty"`
 }
 
 // MetricValue represents a single metric value
@@ -42,28 +120,35 @@ type MetricValue struct {
 	Value     float64   `json:"value"`
 	Unit      string    `json:"unit"`
 	Timestamp time.Time `json:"timestamp"`
+	// Messages carries any CloudWatch MessageData codes returned for this
+	// query, e.g. "Arithmetic-Error" or a truncation notice.
+	Messages []string `json:"messages,omitempty"`
 }
 
+// maxMetricDataQueries is the largest number of MetricDataQuery entries
+// CloudWatch accepts in a single GetMetricData call.
+const maxMetricDataQueries = 500
+
 // Object represents the metrics result structure
 type Object struct {
 	Data map[string]MetricValue `json:"data"`
 }
 
 // Default metrics to fetch if none specified
-var defaultMetrics = []string{
-	"CPUUtilization",
-	"DatabaseConnections",
-	"FreeableMemory",
-	"FreeStorageSpace",
-	"ReadIOPS",
-	"WriteIOPS",
-	"ReadLatency",
-	"WriteLatency",
+var defaultMetrics = []v1beta1.MetricSpec{
+	{Name: "CPUUtilization", Stat: "Average"},
+	{Name: "DatabaseConnections", Stat: "Average"},
+	{Name: "FreeableMemory", Stat: "Average"},
+	{Name: "FreeStorageSpace", Stat: "Average"},
+	{Name: "ReadIOPS", Stat: "Average"},
+	{Name: "WriteIOPS", Stat: "Average"},
+	{Name: "ReadLatency", Stat: "Average"},
+	{Name: "WriteLatency", Stat: "Average"},
 }
 
 // RunFunction runs the Function.
 func (f *Function) RunFunction(ctx context.Context, req *fnv1.RunFunctionRequest) (*fnv1.RunFunctionResponse, error) {
-	f.log.Info("Running RDS metrics function", "tag", req.GetMeta().GetTag())
+	f.log.Info("Running CloudWatch metrics function", "tag", req.GetMeta().GetTag())
 
 	rsp := response.To(req, response.DefaultTTL)
 
@@ -76,16 +161,8 @@ func (f *Function) RunFunction(ctx context.Context, req *fnv1.RunFunctionRequest
 		return rsp, nil //nolint:nilerr // errors are handled in rsp. We should not error main function and proceed with reconciliation
 	}
 
-	// Validate required inputs
-	if in.DatabaseName == "" {
-		response.ConditionFalse(rsp, "FunctionSuccess", "InvalidInput").
-			WithMessage("DatabaseName is required").
-			TargetCompositeAndClaim()
-		return rsp, nil
-	}
-
 	// Get AWS configuration
-	awsConfig, err := f.getAWSConfig(ctx, awsCreds, in.Region)
+	awsConfig, err := f.getAWSConfig(ctx, awsCreds, in.Region, in.CredentialsMode, in.Profile, in.AssumeRole)
 	if err != nil {
 		response.ConditionFalse(rsp, "FunctionSuccess", "AWSConfigError").
 			WithMessage(fmt.Sprintf("Failed to create AWS config: %v", err)).
@@ -93,6 +170,27 @@ func (f *Function) RunFunction(ctx context.Context, req *fnv1.RunFunctionRequest
 		return rsp, nil
 	}
 
+	// Aurora clusters have their own writer/reader-aware collection path.
+	if in.Engine == "aurora" || in.DBClusterIdentifier != "" {
+		return f.runAuroraMode(ctx, req, rsp, in, awsConfig)
+	}
+
+	// Determine the namespace/dimensions to query, falling back to the RDS
+	// convenience mode (DatabaseName -> AWS/RDS + DBInstanceIdentifier)
+	// when Namespace and Dimensions aren't set directly.
+	namespace := in.Namespace
+	dimensions := in.Dimensions
+	if namespace == "" && len(dimensions) == 0 {
+		if in.DatabaseName == "" {
+			response.ConditionFalse(rsp, "FunctionSuccess", "InvalidInput").
+				WithMessage("Either databaseName, or namespace and dimensions, is required").
+				TargetCompositeAndClaim()
+			return rsp, nil
+		}
+		namespace = "AWS/RDS"
+		dimensions = []v1beta1.Dimension{{Name: "DBInstanceIdentifier", Value: in.DatabaseName}}
+	}
+
 	// Create CloudWatch client
 	cwClient := cloudwatch.NewFromConfig(awsConfig)
 
@@ -108,31 +206,62 @@ func (f *Function) RunFunction(ctx context.Context, req *fnv1.RunFunctionRequest
 		period = 300 // 5 minutes default
 	}
 
+	// Validate each metric's effective period before calling CloudWatch.
+	for _, m := range metricsToFetch {
+		effectivePeriod := m.Period
+		if effectivePeriod == 0 {
+			effectivePeriod = period
+		}
+		if err := validatePeriod(effectivePeriod); err != nil {
+			response.ConditionFalse(rsp, "FunctionSuccess", "InvalidPeriod").
+				WithMessage(fmt.Sprintf("metric %s: %v", m.Name, err)).
+				TargetCompositeAndClaim()
+			return rsp, nil
+		}
+	}
+
 	// Fetch metrics from CloudWatch
-	metricsData, err := f.fetchRDSMetrics(ctx, cwClient, in.DatabaseName, metricsToFetch, period)
+	metricsData, err := f.fetchCloudWatchMetrics(ctx, cwClient, namespace, dimensions, metricsToFetch, in.Expressions, period)
 	if err != nil {
 		response.ConditionFalse(rsp, "FunctionSuccess", "CloudWatchError").
-			WithMessage(fmt.Sprintf("Failed to fetch RDS metrics: %v", err)).
+			WithMessage(fmt.Sprintf("Failed to fetch CloudWatch metrics: %v", err)).
 			TargetCompositeAndClaim()
 		return rsp, nil
 	}
 
 	// Create the metrics object
-	rdsMetrics := &RDSMetrics{
-		DatabaseName: in.DatabaseName,
-		Region:       awsConfig.Region,
-		Timestamp:    time.Now(),
-		Metrics:      metricsData,
+	cwMetrics := &CloudWatchMetrics{
+		Namespace:  namespace,
+		Dimensions: dimensions,
+		Region:     awsConfig.Region,
+		Timestamp:  time.Now(),
+		Metrics:    metricsData,
+	}
+
+	// Reconcile CloudWatch alarms, if any are declared. Alarms remain tied
+	// to a specific RDS instance, so they require DatabaseName even when
+	// Namespace/Dimensions were set directly.
+	if len(in.Alarms) > 0 && in.DatabaseName != "" {
+		previouslyManaged := f.previouslyManagedAlarmNames(req, in, cloudWatchStatusKey(namespace, dimensions))
+		alarmsStatus, err := f.reconcileAlarms(ctx, cwClient, in.DatabaseName, in.Alarms, period, in.AlarmHistoryLimit, in.AllowSetAlarmState, previouslyManaged)
+		if err != nil {
+			response.ConditionFalse(rsp, "FunctionSuccess", "CloudWatchAlarmError").
+				WithMessage(fmt.Sprintf("Failed to reconcile CloudWatch alarms: %v", err)).
+				TargetCompositeAndClaim()
+			return rsp, nil
+		}
+		cwMetrics.Alarms = alarmsStatus
 	}
 
 	// Convert to unstructured object
+	statusKey := cloudWatchStatusKey(namespace, dimensions)
 	metricsObj := &unstructured.Unstructured{}
 	metricsObj.SetAPIVersion("rds-metrics.fn.crossplane.io/v1beta1")
-	metricsObj.SetKind("RDSMetrics")
-	metricsObj.SetName(fmt.Sprintf("%s-metrics", in.DatabaseName))
+	metricsObj.SetKind(metricsObjectKind(namespace))
+	metricsObj.SetName(fmt.Sprintf("%s-metrics", statusKey))
 
 	// Convert metrics to JSON and set as object
-	metricsJSON, err := json.Marshal(rdsMetrics)
+	metricsJSON, err := json.Marshal(cwMetrics)
 	if err != nil {
 		response.ConditionFalse(rsp, "FunctionSuccess", "SerializationError").
 			WithMessage(fmt.Sprintf("Failed to serialize metrics: %v", err)).
@@ -150,7 +279,7 @@ func (f *Function) RunFunction(ctx context.Context, req *fnv1.RunFunctionRequest
 
 	metricsObj.Object = metricsMap
 
-	err = f.putMetricsResultToStatus(req, rsp, in, rdsMetrics)
+	err = f.putMetricsResultToStatus(req, rsp, in, statusKey, cwMetrics)
 	if err != nil {
 		response.ConditionFalse(rsp, "FunctionSuccess", "SerializationError").
 			WithMessage(fmt.Sprintf("Failed to put metrics result to status: %v", err)).
@@ -159,14 +288,66 @@ func (f *Function) RunFunction(ctx context.Context, req *fnv1.RunFunctionRequest
 	}
 
 	response.ConditionTrue(rsp, "FunctionSuccess", "Success").
-		WithMessage(fmt.Sprintf("Successfully fetched metrics for RDS instance %s", in.DatabaseName)).
+		WithMessage(fmt.Sprintf("Successfully fetched metrics for %s", statusKey)).
 		TargetCompositeAndClaim()
 
-	f.log.Info("Successfully fetched RDS metrics", "database", in.DatabaseName, "region", awsConfig.Region)
+	f.log.Info("Successfully fetched CloudWatch metrics", "namespace", namespace, "region", awsConfig.Region)
 
 	return rsp, nil
 }
 
+// metricsObjectKind returns "RDSMetrics" for the RDS convenience mode, and
+// "CloudWatchMetrics" for any other namespace, so existing RDS compositions
+// keep seeing the kind they expect.
+func metricsObjectKind(namespace string) string {
+	if namespace == "AWS/RDS" {
+		return "RDSMetrics"
+	}
+	return "CloudWatchMetrics"
+}
+
+// cloudWatchStatusKey derives a status map key from a namespace and its
+// dimensions, e.g. "AWS_RDS.mydb" or "AWS_ElastiCache.my-cluster.node-0001",
+// so multiple invocations targeting the same status field accumulate
+// results for different resources instead of overwriting one another.
+func cloudWatchStatusKey(namespace string, dimensions []v1beta1.Dimension) string {
+	key := strings.ReplaceAll(namespace, "/", "_")
+	for _, d := range dimensions {
+		key += "." + d.Value
+	}
+	return key
+}
+
+// previouslyManagedAlarmNames returns the alarm names this function reported
+// under status.<target>.<statusKey>.alarms on a previous reconcile, so
+// reconcileAlarms can recognize and clean up alarms with a custom Name that
+// wouldn't otherwise be found by the "<dbName>-" prefix convention. A
+// missing or malformed status is treated as "nothing previously managed"
+// rather than an error.
+func (f *Function) previouslyManagedAlarmNames(req *fnv1.RunFunctionRequest, in *v1beta1.Input, statusKey string) []string {
+	xrStatus, _, err := f.getXRAndStatus(req)
+	if err != nil {
+		return nil
+	}
+
+	alarmsField := strings.TrimPrefix(in.Target, "status.") + "." + statusKey + ".alarms"
+	value, ok := GetNestedKey(xrStatus, alarmsField)
+	if !ok {
+		return nil
+	}
+
+	alarms, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	names := make([]string, 0, len(alarms))
+	for name := range alarms {
+		names = append(names, name)
+	}
+	return names
+}
+
 // getXRAndStatus retrieves status and desired XR, handling initialization if needed
 func (f *Function) getXRAndStatus(req *fnv1.RunFunctionRequest) (map[string]interface{}, *resource.Composite, error) {
 	// Get both observed and desired XR
@@ -227,6 +408,29 @@ func ParseNestedKey(key string) ([]string, error) {
 	return parts, nil
 }
 
+// GetNestedKey reads a value from a nested map using the same dot/bracket
+// notation as SetNestedKey. ok is false if any segment of key is missing or
+// not traversable.
+func GetNestedKey(root map[string]interface{}, key string) (interface{}, bool) {
+	parts, err := ParseNestedKey(key)
+	if err != nil {
+		return nil, false
+	}
+
+	var current interface{} = root
+	for _, part := range parts {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[part]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
 // SetNestedKey sets a value to a nested key from a map using dot notation keys.
 func SetNestedKey(root map[string]interface{}, key string, value interface{}) error {
 	parts, err := ParseNestedKey(key)
@@ -260,21 +464,22 @@ func SetNestedKey(root map[string]interface{}, key string, value interface{}) er
 	return nil
 }
 
-// putMetricsResultToStatus processes the metrics results to status
-func (f *Function) putMetricsResultToStatus(req *fnv1.RunFunctionRequest, rsp *fnv1.RunFunctionResponse, in *v1beta1.Input, results *RDSMetrics) error {
+// putMetricsResultToStatus writes results under
+// "<in.Target>.<statusKey>", so a composition that runs this function
+// multiple times for different namespaces/dimensions against the same
+// Target accumulates one entry per resource instead of overwriting the
+// previous result.
+func (f *Function) putMetricsResultToStatus(req *fnv1.RunFunctionRequest, rsp *fnv1.RunFunctionResponse, in *v1beta1.Input, statusKey string, results *CloudWatchMetrics) error {
 	xrStatus, dxr, err := f.getXRAndStatus(req)
 	if err != nil {
 		return err
 	}
 
-	// Prepare the result data
-	resultData := results
-
 	// Update the specific status field
-	statusField := strings.TrimPrefix(in.Target, "status.")
-	err = SetNestedKey(xrStatus, statusField, resultData)
+	statusField := strings.TrimPrefix(in.Target, "status.") + "." + statusKey
+	err = SetNestedKey(xrStatus, statusField, results)
 	if err != nil {
-		return errors.Wrapf(err, "cannot set status field %s to %v", statusField, resultData)
+		return errors.Wrapf(err, "cannot set status field %s to %v", statusField, results)
 	}
 
 	// Write the updated status field back into the composite resource
@@ -289,18 +494,18 @@ func (f *Function) putMetricsResultToStatus(req *fnv1.RunFunctionRequest, rsp *f
 	return nil
 }
 
+// getCreds returns the aws-creds credentials, if any were supplied. Unlike
+// static credentials, the other CredentialsMode values don't require the
+// aws-creds credentials to be set, so a missing map is not an error here.
 func getCreds(req *fnv1.RunFunctionRequest) (map[string]string, error) {
-	var awsCreds map[string]string
+	awsCreds := make(map[string]string)
 	rawCreds := req.GetCredentials()
 
 	if credsData, ok := rawCreds["aws-creds"]; ok {
 		credsMap := credsData.GetCredentialData().GetData()
-		awsCreds = make(map[string]string)
 		for k, v := range credsMap {
 			awsCreds[k] = string(v)
 		}
-	} else {
-		return nil, errors.New("failed to get aws-creds credentials")
 	}
 
 	return awsCreds, nil
@@ -341,87 +546,827 @@ func (f *Function) preserveContext(req *fnv1.RunFunctionRequest, rsp *fnv1.RunFu
 	}
 }
 
-// getAWSConfig creates AWS configuration from the provided credentials
-func (f *Function) getAWSConfig(ctx context.Context, awsCreds map[string]string, region string) (aws.Config, error) {
-	// Extract credentials from the provided map
-	accessKeyID, ok := awsCreds["access-key-id"]
-	if !ok {
-		return aws.Config{}, fmt.Errorf("access-key-id not found in credentials")
+// getAWSConfig resolves the aws.Config to use for a given region, mode and
+// (optionally) role to assume, caching the result for the lifetime of the
+// process so repeated Reconciles don't re-run the credential provider
+// chain.
+func (f *Function) getAWSConfig(ctx context.Context, awsCreds map[string]string, region string, mode v1beta1.CredentialsMode, profile string, assumeRole *v1beta1.AssumeRoleConfig) (aws.Config, error) {
+	if region == "" {
+		region = "us-east-1" // Default region
 	}
 
-	secretAccessKey, ok := awsCreds["secret-access-key"]
-	if !ok {
-		return aws.Config{}, fmt.Errorf("secret-access-key not found in credentials")
+	cacheKey := configCacheKey{
+		region:      region,
+		mode:        mode,
+		profile:     profile,
+		credsDigest: credentialsDigest(awsCreds),
+	}
+	if assumeRole != nil {
+		cacheKey.roleARN = assumeRole.RoleARN
 	}
 
-	// Use the region from input, with default fallback
-	if region == "" {
-		region = "us-east-1" // Default region
+	configCacheMu.Lock()
+	if cfg, ok := configCache[cacheKey]; ok {
+		configCacheMu.Unlock()
+		return cfg, nil
 	}
+	configCacheMu.Unlock()
 
-	// Create AWS config with static credentials
-	cfg, err := config.LoadDefaultConfig(ctx,
-		config.WithRegion(region),
-		config.WithCredentialsProvider(aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
-			return aws.Credentials{
-				AccessKeyID:     accessKeyID,
-				SecretAccessKey: secretAccessKey,
-			}, nil
-		})),
-	)
+	cfg, err := resolveBaseConfig(ctx, awsCreds, region, mode, profile)
 	if err != nil {
 		return aws.Config{}, fmt.Errorf("failed to create AWS config: %w", err)
 	}
 
+	if assumeRole != nil && assumeRole.RoleARN != "" {
+		cfg, err = assumeConfiguredRole(cfg, assumeRole)
+		if err != nil {
+			return aws.Config{}, fmt.Errorf("failed to assume role %s: %w", assumeRole.RoleARN, err)
+		}
+	}
+
+	configCacheMu.Lock()
+	configCache[cacheKey] = cfg
+	configCacheMu.Unlock()
+
+	return cfg, nil
+}
+
+// resolveBaseConfig resolves the aws.Config for the selected
+// CredentialsMode, before any AssumeRole is layered on top. The non-static
+// modes mirror (or narrow) the chain config.LoadDefaultConfig already
+// composes: environment variables, shared config/credentials files, a web
+// identity token (IRSA), ECS/EC2 instance metadata.
+func resolveBaseConfig(ctx context.Context, awsCreds map[string]string, region string, mode v1beta1.CredentialsMode, profile string) (aws.Config, error) {
+	switch mode {
+	case v1beta1.CredentialsModeStatic, "":
+		accessKeyID, ok := awsCreds["access-key-id"]
+		if !ok {
+			return aws.Config{}, fmt.Errorf("access-key-id not found in credentials")
+		}
+
+		secretAccessKey, ok := awsCreds["secret-access-key"]
+		if !ok {
+			return aws.Config{}, fmt.Errorf("secret-access-key not found in credentials")
+		}
+
+		return config.LoadDefaultConfig(ctx,
+			config.WithRegion(region),
+			config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, awsCreds["session-token"])),
+		)
+
+	case v1beta1.CredentialsModeEnvironment:
+		// The default chain already checks AWS_ACCESS_KEY_ID and friends
+		// before falling through to any other source.
+		return config.LoadDefaultConfig(ctx, config.WithRegion(region))
+
+	case v1beta1.CredentialsModeShared:
+		opts := []func(*config.LoadOptions) error{config.WithRegion(region)}
+		if profile != "" {
+			opts = append(opts, config.WithSharedConfigProfile(profile))
+		}
+		return config.LoadDefaultConfig(ctx, opts...)
+
+	case v1beta1.CredentialsModeEC2InstanceRole:
+		cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
+		if err != nil {
+			return aws.Config{}, err
+		}
+		cfg.Credentials = aws.NewCredentialsCache(ec2rolecreds.New(func(o *ec2rolecreds.Options) {
+			o.Client = imds.New(imds.Options{})
+		}))
+		return cfg, nil
+
+	case v1beta1.CredentialsModeWebIdentity:
+		// config.LoadDefaultConfig already resolves a WebIdentityRoleProvider
+		// from AWS_ROLE_ARN and AWS_WEB_IDENTITY_TOKEN_FILE, which is how
+		// EKS projects an IRSA token into the pod.
+		return config.LoadDefaultConfig(ctx, config.WithRegion(region))
+
+	case v1beta1.CredentialsModeAssumeRole:
+		// AssumeRole is layered on afterwards by getAWSConfig; the base
+		// chain resolves the same way the default mode would.
+		return config.LoadDefaultConfig(ctx, config.WithRegion(region))
+
+	default:
+		return aws.Config{}, fmt.Errorf("unsupported credentials mode %q", mode)
+	}
+}
+
+// assumeConfiguredRole wraps cfg's credentials in an STS AssumeRoleProvider
+// for the role described by role.
+func assumeConfiguredRole(cfg aws.Config, role *v1beta1.AssumeRoleConfig) (aws.Config, error) {
+	stsClient := sts.NewFromConfig(cfg)
+
+	provider := stscreds.NewAssumeRoleProvider(stsClient, role.RoleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = role.SessionName
+		if o.RoleSessionName == "" {
+			o.RoleSessionName = defaultAssumeRoleSessionName
+		}
+		if role.ExternalID != "" {
+			o.ExternalID = aws.String(role.ExternalID)
+		}
+	})
+
+	cfg.Credentials = aws.NewCredentialsCache(provider)
 	return cfg, nil
 }
 
-// fetchRDSMetrics fetches RDS metrics from CloudWatch
-func (f *Function) fetchRDSMetrics(ctx context.Context, client *cloudwatch.Client, dbName string, metrics []string, period int32) (map[string]MetricValue, error) {
+// secondsPerDay is used by validatePeriod to judge how far back a period
+// reaches, for CloudWatch's retention/granularity matrix.
+const secondsPerDay = 24 * 60 * 60
+
+// validatePeriod checks that period is acceptable to CloudWatch: a
+// multiple of 60 seconds, and consistent with how far back it looks.
+// CloudWatch only serves 60s granularity for up to 15 days of data, 300s
+// for up to 63 days, and 3600s for up to 455 days; since startTime is
+// derived as now-period, period also stands in for how old the data it
+// requests is.
+func validatePeriod(period int32) error {
+	if period <= 0 {
+		return fmt.Errorf("period must be a positive number of seconds, got %d", period)
+	}
+	if period%60 != 0 {
+		return fmt.Errorf("period must be a multiple of 60 seconds, got %d", period)
+	}
+
+	ageDays := period / secondsPerDay
+	switch {
+	case ageDays > 455:
+		return fmt.Errorf("period %ds looks back further than CloudWatch's 455 day retention window", period)
+	case ageDays > 63 && period < 3600:
+		return fmt.Errorf("period %ds requires at least 3600s (1 hour) granularity once data is more than 63 days old", period)
+	case ageDays > 15 && period < 300:
+		return fmt.Errorf("period %ds requires at least 300s (5 minute) granularity once data is more than 15 days old", period)
+	}
+
+	return nil
+}
+
+// queryResult records where a GetMetricData query's result should be
+// reported in status, and the unit it was requested in (GetMetricData
+// doesn't echo a unit back per-result the way GetMetricStatistics did).
+type queryResult struct {
+	name string
+	unit string
+}
+
+// toCloudWatchDimensions converts the Input's dimension pairs into the
+// types the CloudWatch SDK expects.
+func toCloudWatchDimensions(dimensions []v1beta1.Dimension) []types.Dimension {
+	out := make([]types.Dimension, 0, len(dimensions))
+	for _, d := range dimensions {
+		out = append(out, types.Dimension{Name: aws.String(d.Name), Value: aws.String(d.Value)})
+	}
+	return out
+}
+
+// buildMetricDataQueries turns the requested metrics and expressions into
+// CloudWatch MetricDataQuery entries with deterministic IDs (m0, m1, ...
+// for plain metrics, in order), plus a map from query ID back to the
+// status key and unit the result should be reported under.
+func buildMetricDataQueries(namespace string, dimensions []types.Dimension, metrics []v1beta1.MetricSpec, expressions []v1beta1.MetricExpression, defaultPeriod int32) ([]types.MetricDataQuery, map[string]queryResult) {
+	queries := make([]types.MetricDataQuery, 0, len(metrics)+len(expressions))
+	results := make(map[string]queryResult, len(metrics)+len(expressions))
+
+	for i, m := range metrics {
+		id := fmt.Sprintf("m%d", i)
+		results[id] = queryResult{name: m.Name, unit: m.Unit}
+
+		stat := m.Stat
+		if stat == "" {
+			stat = string(types.StatisticAverage)
+		}
+
+		period := m.Period
+		if period == 0 {
+			period = defaultPeriod
+		}
+
+		metricStat := &types.MetricStat{
+			Metric: &types.Metric{
+				Namespace:  aws.String(namespace),
+				MetricName: aws.String(m.Name),
+				Dimensions: dimensions,
+			},
+			Period: aws.Int32(period),
+			Stat:   aws.String(stat),
+		}
+		if m.Unit != "" {
+			metricStat.Unit = types.StandardUnit(m.Unit)
+		}
+
+		queries = append(queries, types.MetricDataQuery{Id: aws.String(id), MetricStat: metricStat})
+	}
+
+	for _, expr := range expressions {
+		results[expr.ID] = queryResult{name: expr.ID}
+
+		queries = append(queries, types.MetricDataQuery{
+			Id:         aws.String(expr.ID),
+			Expression: aws.String(expr.Expression),
+			Label:      aws.String(expr.Label),
+		})
+	}
+
+	return queries, results
+}
+
+// fetchCloudWatchMetrics fetches metrics (and any derived expressions) for
+// a single namespace/dimension set from CloudWatch using GetMetricData,
+// batching queries in groups of at most maxMetricDataQueries and following
+// NextToken until every page has been retrieved.
+func (f *Function) fetchCloudWatchMetrics(ctx context.Context, client *cloudwatch.Client, namespace string, dimensions []v1beta1.Dimension, metrics []v1beta1.MetricSpec, expressions []v1beta1.MetricExpression, period int32) (map[string]MetricValue, error) {
 	metricsData := make(map[string]MetricValue)
 	endTime := time.Now()
 	startTime := endTime.Add(-time.Duration(period) * time.Second)
 
-	for _, metricName := range metrics {
-		input := &cloudwatch.GetMetricStatisticsInput{
-			Namespace:  aws.String("AWS/RDS"),
-			MetricName: aws.String(metricName),
-			Dimensions: []types.Dimension{
-				{
-					Name:  aws.String("DBInstanceIdentifier"),
-					Value: aws.String(dbName),
-				},
-			},
-			StartTime: aws.Time(startTime),
-			EndTime:   aws.Time(endTime),
-			Period:    aws.Int32(60),
-			Statistics: []types.Statistic{
-				types.StatisticSampleCount,
-			},
-		}
+	queries, idToResult := buildMetricDataQueries(namespace, toCloudWatchDimensions(dimensions), metrics, expressions, period)
 
-		result, err := client.GetMetricStatistics(ctx, input)
-		if err != nil {
-			f.log.Info("Failed to fetch metric", "metric", metricName, "error", err)
-			continue
+	for batchStart := 0; batchStart < len(queries); batchStart += maxMetricDataQueries {
+		batchEnd := batchStart + maxMetricDataQueries
+		if batchEnd > len(queries) {
+			batchEnd = len(queries)
 		}
+		batch := queries[batchStart:batchEnd]
+
+		var nextToken *string
+		for {
+			input := &cloudwatch.GetMetricDataInput{
+				MetricDataQueries: batch,
+				StartTime:         aws.Time(startTime),
+				EndTime:           aws.Time(endTime),
+				NextToken:         nextToken,
+			}
 
-		if len(result.Datapoints) > 0 {
-			// Get the most recent datapoint
-			latest := result.Datapoints[0]
-			for _, dp := range result.Datapoints {
-				if dp.Timestamp.After(*latest.Timestamp) {
-					latest = dp
+			result, err := client.GetMetricData(ctx, input)
+			if err != nil {
+				return nil, errors.Wrap(err, "cannot get metric data")
+			}
+
+			for _, mr := range result.MetricDataResults {
+				qr, ok := idToResult[aws.ToString(mr.Id)]
+				if !ok {
+					continue
+				}
+				name := qr.name
+
+				messages := make([]string, 0, len(mr.Messages))
+				for _, m := range mr.Messages {
+					messages = append(messages, aws.ToString(m.Code))
+				}
+
+				if len(mr.Values) == 0 {
+					// CloudWatch rejected or couldn't evaluate this query
+					// (e.g. a bad Expression): there's no datapoint, but
+					// Messages still carries the reason. Surface it rather
+					// than silently dropping the query, without clobbering
+					// a datapoint a previous page already found.
+					if len(messages) > 0 {
+						existing := metricsData[name]
+						existing.Unit = qr.unit
+						existing.Messages = append(existing.Messages, messages...)
+						metricsData[name] = existing
+					}
+					continue
+				}
+
+				// Values/Timestamps are parallel slices; pick the most
+				// recent datapoint, merging across pages for this query.
+				latestIdx := 0
+				for i, ts := range mr.Timestamps {
+					if ts.After(mr.Timestamps[latestIdx]) {
+						latestIdx = i
+					}
+				}
+				if existing, ok := metricsData[name]; ok && !existing.Timestamp.IsZero() && existing.Timestamp.After(mr.Timestamps[latestIdx]) {
+					continue
+				}
+
+				metricsData[name] = MetricValue{
+					Value:     mr.Values[latestIdx],
+					Unit:      qr.unit,
+					Timestamp: mr.Timestamps[latestIdx],
+					Messages:  messages,
 				}
 			}
 
-			metricsData[metricName] = MetricValue{
-				Value:     *latest.Average,
-				Unit:      string(latest.Unit),
-				Timestamp: *latest.Timestamp,
+			if result.NextToken == nil || aws.ToString(result.NextToken) == "" {
+				break
 			}
+			nextToken = result.NextToken
 		}
 	}
 
 	return metricsData, nil
 }
+
+// defaultAlarmHistoryLimit is used when an Input doesn't set
+// AlarmHistoryLimit.
+const defaultAlarmHistoryLimit = 10
+
+// alarmName returns the CloudWatch alarm name for spec, defaulting to
+// "<dbName>-<metric>" when spec.Name is unset.
+func alarmName(dbName string, spec v1beta1.AlarmSpec) string {
+	if spec.Name != "" {
+		return spec.Name
+	}
+	return fmt.Sprintf("%s-%s", dbName, spec.Metric)
+}
+
+// putMetricAlarmInput builds the PutMetricAlarmInput that would bring a
+// single alarm in line with spec.
+func putMetricAlarmInput(dbName string, spec v1beta1.AlarmSpec, defaultPeriod int32) *cloudwatch.PutMetricAlarmInput {
+	statistic := spec.Statistic
+	if statistic == "" {
+		statistic = "Average"
+	}
+
+	period := spec.Period
+	if period == 0 {
+		period = defaultPeriod
+	}
+
+	input := &cloudwatch.PutMetricAlarmInput{
+		AlarmName:          aws.String(alarmName(dbName, spec)),
+		Namespace:          aws.String("AWS/RDS"),
+		MetricName:         aws.String(spec.Metric),
+		Statistic:          types.Statistic(statistic),
+		ComparisonOperator: types.ComparisonOperator(spec.ComparisonOperator),
+		Threshold:          aws.Float64(spec.Threshold),
+		EvaluationPeriods:  aws.Int32(spec.EvaluationPeriods),
+		Period:             aws.Int32(period),
+		Dimensions: []types.Dimension{
+			{
+				Name:  aws.String("DBInstanceIdentifier"),
+				Value: aws.String(dbName),
+			},
+		},
+	}
+
+	if spec.SNSTopicARN != "" {
+		input.AlarmActions = []string{spec.SNSTopicARN}
+		input.OKActions = []string{spec.SNSTopicARN}
+	}
+
+	return input
+}
+
+// alarmDrifted reports whether an existing CloudWatch alarm differs from
+// the PutMetricAlarmInput that represents its desired configuration.
+func alarmDrifted(existing types.MetricAlarm, desired *cloudwatch.PutMetricAlarmInput) bool {
+	if aws.ToString(existing.MetricName) != aws.ToString(desired.MetricName) {
+		return true
+	}
+	if existing.Statistic != desired.Statistic {
+		return true
+	}
+	if existing.ComparisonOperator != desired.ComparisonOperator {
+		return true
+	}
+	if aws.ToFloat64(existing.Threshold) != aws.ToFloat64(desired.Threshold) {
+		return true
+	}
+	if aws.ToInt32(existing.EvaluationPeriods) != aws.ToInt32(desired.EvaluationPeriods) {
+		return true
+	}
+	if aws.ToInt32(existing.Period) != aws.ToInt32(desired.Period) {
+		return true
+	}
+	if actionsDiffer(existing.AlarmActions, desired.AlarmActions) {
+		return true
+	}
+	if actionsDiffer(existing.OKActions, desired.OKActions) {
+		return true
+	}
+	return false
+}
+
+// actionsDiffer reports whether two ordered lists of alarm action ARNs
+// differ.
+func actionsDiffer(existing, desired []string) bool {
+	if len(existing) != len(desired) {
+		return true
+	}
+	for i, action := range existing {
+		if action != desired[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// alarmHistory fetches the most recent state transitions for a single
+// alarm, newest first, capped at limit entries.
+func (f *Function) alarmHistory(ctx context.Context, client *cloudwatch.Client, name string, limit int32) ([]AlarmHistoryEvent, error) {
+	result, err := client.DescribeAlarmHistory(ctx, &cloudwatch.DescribeAlarmHistoryInput{
+		AlarmName:       aws.String(name),
+		HistoryItemType: types.HistoryItemTypeStateUpdate,
+		MaxRecords:      aws.Int32(limit),
+		ScanBy:          types.ScanByTimestampDescending,
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot describe alarm history for %s", name)
+	}
+
+	events := make([]AlarmHistoryEvent, 0, len(result.AlarmHistoryItems))
+	for _, item := range result.AlarmHistoryItems {
+		events = append(events, AlarmHistoryEvent{
+			Timestamp: aws.ToTime(item.Timestamp),
+			Summary:   aws.ToString(item.HistorySummary),
+		})
+	}
+
+	return events, nil
+}
+
+// reconcileAlarms brings the CloudWatch alarms for dbName in line with
+// alarms: missing or drifted alarms are created or updated via
+// PutMetricAlarm, and alarms this function previously created but that are
+// no longer desired are removed via DeleteAlarms. It returns the
+// reconciled state of every desired alarm, keyed by alarm name.
+//
+// Alarm discovery can't rely solely on the "<dbName>-" default naming
+// convention, since AlarmSpec.Name lets a caller pick an arbitrary name: a
+// custom name wouldn't match an AlarmNamePrefix scan, so it would never be
+// recognized as already existing (and would be PutMetricAlarm'd every
+// reconcile) nor cleaned up once removed from alarms. previouslyManaged is
+// the set of alarm names this function reported as managed on the prior
+// reconcile (see previouslyManagedAlarmNames); together with the current
+// desired names it's used to look up alarms by name directly, so custom
+// names are tracked correctly regardless of the prefix.
+func (f *Function) reconcileAlarms(ctx context.Context, client *cloudwatch.Client, dbName string, alarms []v1beta1.AlarmSpec, defaultPeriod, historyLimit int32, allowSetAlarmState bool, previouslyManaged []string) (map[string]AlarmStatus, error) {
+	if historyLimit == 0 {
+		historyLimit = defaultAlarmHistoryLimit
+	}
+
+	desired := make(map[string]*cloudwatch.PutMetricAlarmInput, len(alarms))
+	for _, spec := range alarms {
+		name := alarmName(dbName, spec)
+		desired[name] = putMetricAlarmInput(dbName, spec, defaultPeriod)
+	}
+
+	// The "<dbName>-" prefix still covers the default naming convention in
+	// a single cheap call.
+	existingByPrefix, err := client.DescribeAlarms(ctx, &cloudwatch.DescribeAlarmsInput{
+		AlarmNamePrefix: aws.String(dbName + "-"),
+		AlarmTypes:      []types.AlarmType{types.AlarmTypeMetricAlarm},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot describe alarms")
+	}
+
+	existing := make(map[string]types.MetricAlarm, len(existingByPrefix.MetricAlarms))
+	for _, alarm := range existingByPrefix.MetricAlarms {
+		existing[aws.ToString(alarm.AlarmName)] = alarm
+	}
+
+	// Look up, by exact name, any desired or previously-managed alarm the
+	// prefix scan didn't already find. This is what catches custom names.
+	lookupNames := make(map[string]struct{})
+	for name := range desired {
+		if _, ok := existing[name]; !ok {
+			lookupNames[name] = struct{}{}
+		}
+	}
+	for _, name := range previouslyManaged {
+		if _, ok := existing[name]; !ok {
+			lookupNames[name] = struct{}{}
+		}
+	}
+	if len(lookupNames) > 0 {
+		names := make([]string, 0, len(lookupNames))
+		for name := range lookupNames {
+			names = append(names, name)
+		}
+		byName, err := client.DescribeAlarms(ctx, &cloudwatch.DescribeAlarmsInput{
+			AlarmNames: names,
+			AlarmTypes: []types.AlarmType{types.AlarmTypeMetricAlarm},
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "cannot describe alarms by name")
+		}
+		for _, alarm := range byName.MetricAlarms {
+			existing[aws.ToString(alarm.AlarmName)] = alarm
+		}
+	}
+
+	// Anything that exists now, or that we reported managing last time
+	// (even if it's since been deleted out-of-band), is a candidate for
+	// cleanup if it's no longer desired.
+	managed := make(map[string]struct{}, len(existing)+len(previouslyManaged))
+	for name := range existing {
+		managed[name] = struct{}{}
+	}
+	for _, name := range previouslyManaged {
+		managed[name] = struct{}{}
+	}
+
+	var stale []string
+	for name := range managed {
+		if _, wanted := desired[name]; !wanted {
+			stale = append(stale, name)
+		}
+	}
+
+	for name, input := range desired {
+		if existingAlarm, ok := existing[name]; !ok || alarmDrifted(existingAlarm, input) {
+			if _, err := client.PutMetricAlarm(ctx, input); err != nil {
+				return nil, errors.Wrapf(err, "cannot put metric alarm %s", name)
+			}
+		}
+	}
+
+	if len(stale) > 0 {
+		if _, err := client.DeleteAlarms(ctx, &cloudwatch.DeleteAlarmsInput{AlarmNames: stale}); err != nil {
+			return nil, errors.Wrap(err, "cannot delete stale alarms")
+		}
+	}
+
+	for _, spec := range alarms {
+		if spec.ForceState == "" {
+			continue
+		}
+		if !allowSetAlarmState {
+			f.log.Info("Ignoring forceState: allowSetAlarmState is false", "alarm", alarmName(dbName, spec))
+			continue
+		}
+		_, err := client.SetAlarmState(ctx, &cloudwatch.SetAlarmStateInput{
+			AlarmName:   aws.String(alarmName(dbName, spec)),
+			StateValue:  types.StateValue(spec.ForceState),
+			StateReason: aws.String(spec.ForceStateReason),
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot set alarm state for %s", alarmName(dbName, spec))
+		}
+	}
+
+	statuses := make(map[string]AlarmStatus, len(alarms))
+	for _, spec := range alarms {
+		name := alarmName(dbName, spec)
+
+		result, err := client.DescribeAlarms(ctx, &cloudwatch.DescribeAlarmsInput{AlarmNames: []string{name}})
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot describe alarm %s", name)
+		}
+
+		status := AlarmStatus{State: string(types.StateValueInsufficientData)}
+		if len(result.MetricAlarms) > 0 {
+			status.State = string(result.MetricAlarms[0].StateValue)
+			status.Reason = aws.ToString(result.MetricAlarms[0].StateReason)
+		}
+
+		history, err := f.alarmHistory(ctx, client, name, historyLimit)
+		if err != nil {
+			return nil, err
+		}
+		status.History = history
+
+		statuses[name] = status
+	}
+
+	return statuses, nil
+}
+
+// clusterTopologyTTL bounds how long a discovered Aurora topology is
+// reused before DescribeDBClusters/DescribeDBInstances run again.
+const clusterTopologyTTL = 60 * time.Second
+
+// auroraClusterMetrics are the cluster-wide metrics collected under the
+// DBClusterIdentifier dimension for an Aurora cluster.
+var auroraClusterMetrics = []v1beta1.MetricSpec{
+	{Name: "VolumeBytesUsed", Stat: "Average"},
+	{Name: "AuroraReplicaLag", Stat: "Average"},
+	{Name: "BufferCacheHitRatio", Stat: "Average"},
+}
+
+// clusterTopology is a discovered Aurora cluster's writer and reader
+// instance identifiers.
+type clusterTopology struct {
+	Writer  string
+	Readers []string
+}
+
+type clusterTopologyCacheEntry struct {
+	topology  clusterTopology
+	expiresAt time.Time
+}
+
+// clusterTopologyCache holds discovered Aurora topologies keyed by cluster
+// identifier, for clusterTopologyTTL at a time.
+var (
+	clusterTopologyCacheMu sync.Mutex
+	clusterTopologyCache   = make(map[string]clusterTopologyCacheEntry)
+)
+
+// clusterTopologyCached returns the cached topology for clusterID if it
+// hasn't expired, discovering and caching it otherwise.
+func (f *Function) clusterTopologyCached(ctx context.Context, client *rds.Client, clusterID string) (clusterTopology, error) {
+	clusterTopologyCacheMu.Lock()
+	if entry, ok := clusterTopologyCache[clusterID]; ok && time.Now().Before(entry.expiresAt) {
+		clusterTopologyCacheMu.Unlock()
+		return entry.topology, nil
+	}
+	clusterTopologyCacheMu.Unlock()
+
+	topology, err := discoverClusterTopology(ctx, client, clusterID)
+	if err != nil {
+		return clusterTopology{}, err
+	}
+
+	clusterTopologyCacheMu.Lock()
+	clusterTopologyCache[clusterID] = clusterTopologyCacheEntry{topology: topology, expiresAt: time.Now().Add(clusterTopologyTTL)}
+	clusterTopologyCacheMu.Unlock()
+
+	return topology, nil
+}
+
+// discoverClusterTopology resolves clusterID's writer and reader instance
+// identifiers via DescribeDBClusters, cross-checked against
+// DescribeDBInstances so a member that's been removed from the cluster
+// doesn't leave a stale entry behind.
+func discoverClusterTopology(ctx context.Context, client *rds.Client, clusterID string) (clusterTopology, error) {
+	clustersOut, err := client.DescribeDBClusters(ctx, &rds.DescribeDBClustersInput{
+		DBClusterIdentifier: aws.String(clusterID),
+	})
+	if err != nil {
+		return clusterTopology{}, errors.Wrapf(err, "cannot describe DB cluster %s", clusterID)
+	}
+	if len(clustersOut.DBClusters) == 0 {
+		return clusterTopology{}, fmt.Errorf("DB cluster %s not found", clusterID)
+	}
+
+	instancesOut, err := client.DescribeDBInstances(ctx, &rds.DescribeDBInstancesInput{
+		Filters: []rdstypes.Filter{
+			{Name: aws.String("db-cluster-id"), Values: []string{clusterID}},
+		},
+	})
+	if err != nil {
+		return clusterTopology{}, errors.Wrapf(err, "cannot describe instances for DB cluster %s", clusterID)
+	}
+
+	known := make(map[string]bool, len(instancesOut.DBInstances))
+	for _, instance := range instancesOut.DBInstances {
+		known[aws.ToString(instance.DBInstanceIdentifier)] = true
+	}
+
+	var topology clusterTopology
+	for _, member := range clustersOut.DBClusters[0].DBClusterMembers {
+		id := aws.ToString(member.DBInstanceIdentifier)
+		if !known[id] {
+			continue
+		}
+		if aws.ToBool(member.IsClusterWriter) {
+			topology.Writer = id
+		} else {
+			topology.Readers = append(topology.Readers, id)
+		}
+	}
+
+	return topology, nil
+}
+
+// roleDimensions pairs an Aurora instance with its Role, so per-instance
+// queries can be addressed by writer/reader role.
+func roleDimensions(instanceID, role string) []v1beta1.Dimension {
+	return []v1beta1.Dimension{
+		{Name: "DBInstanceIdentifier", Value: instanceID},
+		{Name: "Role", Value: role},
+	}
+}
+
+// runAuroraMode collects cluster-wide metrics plus per-instance metrics
+// for every writer and reader in an Aurora cluster, and writes them to
+// status.<target>.cluster, status.<target>.writer and
+// status.<target>.readers.
+func (f *Function) runAuroraMode(ctx context.Context, req *fnv1.RunFunctionRequest, rsp *fnv1.RunFunctionResponse, in *v1beta1.Input, awsConfig aws.Config) (*fnv1.RunFunctionResponse, error) {
+	if in.DBClusterIdentifier == "" {
+		response.ConditionFalse(rsp, "FunctionSuccess", "InvalidInput").
+			WithMessage("dbClusterIdentifier is required when engine is aurora").
+			TargetCompositeAndClaim()
+		return rsp, nil
+	}
+
+	rdsClient := rds.NewFromConfig(awsConfig)
+	cwClient := cloudwatch.NewFromConfig(awsConfig)
+
+	topology, err := f.clusterTopologyCached(ctx, rdsClient, in.DBClusterIdentifier)
+	if err != nil {
+		response.ConditionFalse(rsp, "FunctionSuccess", "ClusterNotFound").
+			WithMessage(fmt.Sprintf("Failed to resolve Aurora cluster %s: %v", in.DBClusterIdentifier, err)).
+			TargetCompositeAndClaim()
+		return rsp, nil
+	}
+
+	metricsToFetch := in.Metrics
+	if len(metricsToFetch) == 0 {
+		metricsToFetch = defaultMetrics
+	}
+
+	period := in.Period
+	if period == 0 {
+		period = 300 // 5 minutes default
+	}
+
+	for _, m := range append(append([]v1beta1.MetricSpec{}, metricsToFetch...), auroraClusterMetrics...) {
+		effectivePeriod := m.Period
+		if effectivePeriod == 0 {
+			effectivePeriod = period
+		}
+		if err := validatePeriod(effectivePeriod); err != nil {
+			response.ConditionFalse(rsp, "FunctionSuccess", "InvalidPeriod").
+				WithMessage(fmt.Sprintf("metric %s: %v", m.Name, err)).
+				TargetCompositeAndClaim()
+			return rsp, nil
+		}
+	}
+
+	// in.Expressions reference the m0..mN query IDs buildMetricDataQueries
+	// assigns to metricsToFetch, which aren't the same queries as
+	// auroraClusterMetrics: passing them here would let an expression
+	// reference an out-of-range ID and fail the whole cluster fetch. Expressions
+	// only ever apply to the per-instance (writer/reader) metric queries.
+	clusterDims := []v1beta1.Dimension{{Name: "DBClusterIdentifier", Value: in.DBClusterIdentifier}}
+	clusterData, err := f.fetchCloudWatchMetrics(ctx, cwClient, "AWS/RDS", clusterDims, auroraClusterMetrics, nil, period)
+	if err != nil {
+		response.ConditionFalse(rsp, "FunctionSuccess", "CloudWatchError").
+			WithMessage(fmt.Sprintf("Failed to fetch Aurora cluster metrics: %v", err)).
+			TargetCompositeAndClaim()
+		return rsp, nil
+	}
+	cluster := CloudWatchMetrics{Namespace: "AWS/RDS", Dimensions: clusterDims, Region: awsConfig.Region, Timestamp: time.Now(), Metrics: clusterData}
+
+	var writer *CloudWatchMetrics
+	if topology.Writer != "" {
+		dims := roleDimensions(topology.Writer, "WRITER")
+		data, err := f.fetchCloudWatchMetrics(ctx, cwClient, "AWS/RDS", dims, metricsToFetch, in.Expressions, period)
+		if err != nil {
+			response.ConditionFalse(rsp, "FunctionSuccess", "CloudWatchError").
+				WithMessage(fmt.Sprintf("Failed to fetch writer %s metrics: %v", topology.Writer, err)).
+				TargetCompositeAndClaim()
+			return rsp, nil
+		}
+		writer = &CloudWatchMetrics{Namespace: "AWS/RDS", Dimensions: dims, Region: awsConfig.Region, Timestamp: time.Now(), Metrics: data}
+	}
+
+	readers := make([]CloudWatchMetrics, 0, len(topology.Readers))
+	for _, readerID := range topology.Readers {
+		dims := roleDimensions(readerID, "READER")
+		data, err := f.fetchCloudWatchMetrics(ctx, cwClient, "AWS/RDS", dims, metricsToFetch, in.Expressions, period)
+		if err != nil {
+			response.ConditionFalse(rsp, "FunctionSuccess", "CloudWatchError").
+				WithMessage(fmt.Sprintf("Failed to fetch reader %s metrics: %v", readerID, err)).
+				TargetCompositeAndClaim()
+			return rsp, nil
+		}
+		readers = append(readers, CloudWatchMetrics{Namespace: "AWS/RDS", Dimensions: dims, Region: awsConfig.Region, Timestamp: time.Now(), Metrics: data})
+	}
+
+	if err := f.putAuroraResultToStatus(req, rsp, in, cluster, writer, readers); err != nil {
+		response.ConditionFalse(rsp, "FunctionSuccess", "SerializationError").
+			WithMessage(fmt.Sprintf("Failed to put Aurora metrics result to status: %v", err)).
+			TargetCompositeAndClaim()
+		return rsp, nil
+	}
+
+	response.ConditionTrue(rsp, "FunctionSuccess", "Success").
+		WithMessage(fmt.Sprintf("Successfully fetched Aurora metrics for cluster %s", in.DBClusterIdentifier)).
+		TargetCompositeAndClaim()
+
+	f.log.Info("Successfully fetched Aurora cluster metrics", "cluster", in.DBClusterIdentifier, "writer", topology.Writer, "readers", len(topology.Readers))
+
+	return rsp, nil
+}
+
+// putAuroraResultToStatus writes cluster, writer and reader results under
+// status.<target>.cluster, status.<target>.writer and
+// status.<target>.readers respectively.
+func (f *Function) putAuroraResultToStatus(req *fnv1.RunFunctionRequest, rsp *fnv1.RunFunctionResponse, in *v1beta1.Input, cluster CloudWatchMetrics, writer *CloudWatchMetrics, readers []CloudWatchMetrics) error {
+	xrStatus, dxr, err := f.getXRAndStatus(req)
+	if err != nil {
+		return err
+	}
+
+	base := strings.TrimPrefix(in.Target, "status.")
+
+	if err := SetNestedKey(xrStatus, base+".cluster", cluster); err != nil {
+		return errors.Wrapf(err, "cannot set status field %s.cluster", base)
+	}
+	if writer != nil {
+		if err := SetNestedKey(xrStatus, base+".writer", writer); err != nil {
+			return errors.Wrapf(err, "cannot set status field %s.writer", base)
+		}
+	}
+	if err := SetNestedKey(xrStatus, base+".readers", readers); err != nil {
+		return errors.Wrapf(err, "cannot set status field %s.readers", base)
+	}
+
+	if err := dxr.Resource.SetValue("status", xrStatus); err != nil {
+		return errors.Wrap(err, "cannot write updated status back into composite resource")
+	}
+
+	if err := response.SetDesiredCompositeResource(rsp, dxr); err != nil {
+		return errors.Wrapf(err, "cannot set desired composite resource in %T", rsp)
+	}
+
+	return nil
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/cwilhit/function-rds-metrics/input/v1beta1"
+)
+
+func TestBuildMetricDataQueries(t *testing.T) {
+	dims := []types.Dimension{{Name: aws.String("DBInstanceIdentifier"), Value: aws.String("mydb")}}
+
+	cases := map[string]struct {
+		metrics     []v1beta1.MetricSpec
+		expressions []v1beta1.MetricExpression
+		period      int32
+		wantIDs     []string
+		wantNames   map[string]string
+		wantStats   map[string]string
+		wantPeriods map[string]int32
+	}{
+		"single metric default stat and period": {
+			metrics: []v1beta1.MetricSpec{{Name: "CPUUtilization"}},
+			period:  300,
+			wantIDs: []string{"m0"},
+			wantNames: map[string]string{
+				"m0": "CPUUtilization",
+			},
+			wantStats: map[string]string{
+				"m0": string(types.StatisticAverage),
+			},
+			wantPeriods: map[string]int32{
+				"m0": 300,
+			},
+		},
+		"metric overrides stat, unit and period": {
+			metrics: []v1beta1.MetricSpec{{Name: "ReadLatency", Stat: "p99", Unit: "Seconds", Period: 60}},
+			period:  300,
+			wantIDs: []string{"m0"},
+			wantStats: map[string]string{
+				"m0": "p99",
+			},
+			wantPeriods: map[string]int32{
+				"m0": 60,
+			},
+		},
+		"multiple metrics get sequential ids": {
+			metrics: []v1beta1.MetricSpec{{Name: "ReadIOPS"}, {Name: "WriteIOPS"}, {Name: "FreeStorageSpace"}},
+			period:  300,
+			wantIDs: []string{"m0", "m1", "m2"},
+			wantNames: map[string]string{
+				"m0": "ReadIOPS",
+				"m1": "WriteIOPS",
+				"m2": "FreeStorageSpace",
+			},
+		},
+		"expression queries use their own id and carry no MetricStat": {
+			metrics:     []v1beta1.MetricSpec{{Name: "ReadIOPS"}, {Name: "WriteIOPS"}},
+			expressions: []v1beta1.MetricExpression{{ID: "iops", Expression: "m0 + m1", Label: "Total IOPS"}},
+			period:      300,
+			wantIDs:     []string{"m0", "m1", "iops"},
+			wantNames: map[string]string{
+				"iops": "iops",
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			queries, results := buildMetricDataQueries("AWS/RDS", dims, tc.metrics, tc.expressions, tc.period)
+
+			if len(queries) != len(tc.wantIDs) {
+				t.Fatalf("got %d queries, want %d", len(queries), len(tc.wantIDs))
+			}
+
+			seen := make(map[string]types.MetricDataQuery, len(queries))
+			for _, q := range queries {
+				seen[aws.ToString(q.Id)] = q
+			}
+
+			for _, id := range tc.wantIDs {
+				q, ok := seen[id]
+				if !ok {
+					t.Fatalf("missing query with id %q", id)
+				}
+
+				if wantName, ok := tc.wantNames[id]; ok {
+					if got := results[id].name; got != wantName {
+						t.Errorf("query %s: got name %q, want %q", id, got, wantName)
+					}
+				}
+
+				if wantStat, ok := tc.wantStats[id]; ok {
+					if q.MetricStat == nil {
+						t.Fatalf("query %s: expected a MetricStat, got an expression query", id)
+					}
+					if got := aws.ToString(q.MetricStat.Stat); got != wantStat {
+						t.Errorf("query %s: got stat %q, want %q", id, got, wantStat)
+					}
+				}
+
+				if wantPeriod, ok := tc.wantPeriods[id]; ok {
+					if q.MetricStat == nil {
+						t.Fatalf("query %s: expected a MetricStat, got an expression query", id)
+					}
+					if got := aws.ToInt32(q.MetricStat.Period); got != wantPeriod {
+						t.Errorf("query %s: got period %d, want %d", id, got, wantPeriod)
+					}
+				}
+			}
+
+			for _, expr := range tc.expressions {
+				q, ok := seen[expr.ID]
+				if !ok {
+					t.Fatalf("missing expression query with id %q", expr.ID)
+				}
+				if q.MetricStat != nil {
+					t.Errorf("expression query %s: got a MetricStat, want none", expr.ID)
+				}
+				if got := aws.ToString(q.Expression); got != expr.Expression {
+					t.Errorf("expression query %s: got expression %q, want %q", expr.ID, got, expr.Expression)
+				}
+			}
+		})
+	}
+}
+
+func TestAlarmDrifted(t *testing.T) {
+	spec := v1beta1.AlarmSpec{
+		Metric:             "CPUUtilization",
+		ComparisonOperator: "GreaterThanThreshold",
+		Threshold:          80,
+		EvaluationPeriods:  3,
+		SNSTopicARN:        "arn:aws:sns:us-east-1:123456789012:alerts",
+	}
+	desired := putMetricAlarmInput("mydb", spec, 300)
+
+	matching := func() types.MetricAlarm {
+		return types.MetricAlarm{
+			MetricName:         desired.MetricName,
+			Statistic:          desired.Statistic,
+			ComparisonOperator: desired.ComparisonOperator,
+			Threshold:          desired.Threshold,
+			EvaluationPeriods:  desired.EvaluationPeriods,
+			Period:             desired.Period,
+			AlarmActions:       desired.AlarmActions,
+			OKActions:          desired.OKActions,
+		}
+	}
+
+	cases := map[string]struct {
+		mutate      func(types.MetricAlarm) types.MetricAlarm
+		wantDrifted bool
+	}{
+		"identical config is not drifted": {
+			mutate:      func(a types.MetricAlarm) types.MetricAlarm { return a },
+			wantDrifted: false,
+		},
+		"different threshold is drifted": {
+			mutate: func(a types.MetricAlarm) types.MetricAlarm {
+				a.Threshold = aws.Float64(90)
+				return a
+			},
+			wantDrifted: true,
+		},
+		"different comparison operator is drifted": {
+			mutate: func(a types.MetricAlarm) types.MetricAlarm {
+				a.ComparisonOperator = types.ComparisonOperatorLessThanThreshold
+				return a
+			},
+			wantDrifted: true,
+		},
+		"different evaluation periods is drifted": {
+			mutate: func(a types.MetricAlarm) types.MetricAlarm {
+				a.EvaluationPeriods = aws.Int32(5)
+				return a
+			},
+			wantDrifted: true,
+		},
+		"different period is drifted": {
+			mutate: func(a types.MetricAlarm) types.MetricAlarm {
+				a.Period = aws.Int32(60)
+				return a
+			},
+			wantDrifted: true,
+		},
+		"different AlarmActions is drifted": {
+			mutate: func(a types.MetricAlarm) types.MetricAlarm {
+				a.AlarmActions = []string{"arn:aws:sns:us-east-1:123456789012:other"}
+				return a
+			},
+			wantDrifted: true,
+		},
+		"different OKActions is drifted": {
+			mutate: func(a types.MetricAlarm) types.MetricAlarm {
+				a.OKActions = []string{"arn:aws:sns:us-east-1:123456789012:other"}
+				return a
+			},
+			wantDrifted: true,
+		},
+		"missing OKActions is drifted": {
+			mutate: func(a types.MetricAlarm) types.MetricAlarm {
+				a.OKActions = nil
+				return a
+			},
+			wantDrifted: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			existing := tc.mutate(matching())
+			if got := alarmDrifted(existing, desired); got != tc.wantDrifted {
+				t.Errorf("alarmDrifted() = %v, want %v", got, tc.wantDrifted)
+			}
+		})
+	}
+}
+
+func TestValidatePeriod(t *testing.T) {
+	cases := map[string]struct {
+		period  int32
+		wantErr bool
+	}{
+		"zero is invalid":                  {period: 0, wantErr: true},
+		"negative is invalid":              {period: -60, wantErr: true},
+		"not a multiple of 60 is invalid":  {period: 90, wantErr: true},
+		"a typical 5 minute period":        {period: 300, wantErr: false},
+		"exactly 455 days is valid":        {period: 455 * secondsPerDay, wantErr: false},
+		"further back than 455 days fails": {period: 456 * secondsPerDay, wantErr: true},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := validatePeriod(tc.period)
+			if tc.wantErr && err == nil {
+				t.Errorf("validatePeriod(%d) = nil, want an error", tc.period)
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("validatePeriod(%d) = %v, want nil", tc.period, err)
+			}
+		})
+	}
+}
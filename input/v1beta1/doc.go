@@ -0,0 +1,5 @@
+// Package v1beta1 contains the input type for this Function.
+// +kubebuilder:object:generate=true
+// +groupName=rds-metrics.fn.crossplane.io
+// +versionName=v1beta1
+package v1beta1
@@ -0,0 +1,259 @@
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// This isn't a custom resource, in the sense that we never install its CRD.
+// It is compatible with the CRD interface, though.
+
+// +kubebuilder:object:root=true
+
+// Input can be used to provide input to this Function.
+type Input struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// DatabaseName is the RDS DBInstanceIdentifier to fetch metrics for.
+	// This is a convenience for RDS: it's equivalent to setting Namespace
+	// to AWS/RDS and Dimensions to [{name: DBInstanceIdentifier, value:
+	// <DatabaseName>}]. Required unless Namespace and Dimensions are set
+	// directly.
+	// +optional
+	DatabaseName string `json:"databaseName,omitempty"`
+
+	// Namespace is the CloudWatch namespace to query, e.g. AWS/ELB,
+	// AWS/ApplicationELB, AWS/ElastiCache or AWS/Lambda. Required unless
+	// DatabaseName is set.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Dimensions identifies the specific resource(s) within Namespace to
+	// query, e.g. [{name: LoadBalancerName, value: my-elb}]. Required
+	// unless DatabaseName is set.
+	// +optional
+	Dimensions []Dimension `json:"dimensions,omitempty"`
+
+	// Engine selects instance-vs-cluster-aware collection. Set to "aurora"
+	// (or set DBClusterIdentifier directly) to discover an Aurora
+	// cluster's writer and reader instances and collect cluster-wide and
+	// per-instance, per-role metrics for all of them.
+	// +optional
+	// +kubebuilder:validation:Enum=aurora
+	Engine string `json:"engine,omitempty"`
+
+	// DBClusterIdentifier is the Aurora cluster to collect metrics for.
+	// Setting it implies Engine aurora.
+	// +optional
+	DBClusterIdentifier string `json:"dbClusterIdentifier,omitempty"`
+
+	// Region is the AWS region the target resource lives in. Defaults to
+	// us-east-1 when omitted.
+	// +optional
+	Region string `json:"region,omitempty"`
+
+	// Metrics is the list of CloudWatch metrics to fetch. Defaults to a
+	// standard set of RDS metrics when omitted.
+	// +optional
+	Metrics []MetricSpec `json:"metrics,omitempty"`
+
+	// Expressions are derived metrics computed from other queries in the
+	// same CloudWatch GetMetricData call, e.g. IOPS = ReadIOPS + WriteIOPS.
+	// +optional
+	Expressions []MetricExpression `json:"expressions,omitempty"`
+
+	// Period is the metric lookback window, in seconds.
+	// +optional
+	Period int32 `json:"period,omitempty"`
+
+	// Target is the status field the fetched metrics should be written to,
+	// e.g. status.atProvider.metrics.
+	Target string `json:"target"`
+
+	// CredentialsMode selects how the function resolves AWS credentials.
+	// Defaults to static, i.e. the access-key-id/secret-access-key pair in
+	// the aws-creds credentials, for backward compatibility.
+	// +optional
+	// +kubebuilder:validation:Enum=static;environment;shared;webIdentity;ec2InstanceRole;assumeRole
+	CredentialsMode CredentialsMode `json:"credentialsMode,omitempty"`
+
+	// Profile is the shared config/credentials file profile to use when
+	// CredentialsMode is shared.
+	// +optional
+	Profile string `json:"profile,omitempty"`
+
+	// AssumeRole configures an IAM role the function should assume via STS
+	// on top of whatever CredentialsMode resolves, which is the common
+	// pattern for cross-account RDS telemetry in Crossplane compositions.
+	// +optional
+	AssumeRole *AssumeRoleConfig `json:"assumeRole,omitempty"`
+
+	// Alarms declares the CloudWatch alarms that should exist for
+	// DatabaseName. When non-empty, the function reconciles AWS/RDS
+	// alarms (creating, updating and deleting them as needed) in addition
+	// to fetching metrics.
+	// +optional
+	Alarms []AlarmSpec `json:"alarms,omitempty"`
+
+	// AlarmHistoryLimit caps how many recent state transitions are
+	// surfaced into status per alarm. Defaults to 10.
+	// +optional
+	AlarmHistoryLimit int32 `json:"alarmHistoryLimit,omitempty"`
+
+	// AllowSetAlarmState permits an AlarmSpec's ForceState to be applied
+	// via CloudWatch SetAlarmState instead of being evaluated from real
+	// metric data. This is an escape hatch for exercising compositions
+	// end-to-end without waiting for a real breach, and should never be
+	// enabled against a production alarm.
+	// +optional
+	AllowSetAlarmState bool `json:"allowSetAlarmState,omitempty"`
+}
+
+// AlarmSpec declares a single CloudWatch alarm that should exist for the
+// target RDS instance.
+type AlarmSpec struct {
+	// Name is the CloudWatch alarm name. Defaults to
+	// "<databaseName>-<metric>" when omitted.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Metric is the CloudWatch metric name the alarm watches, e.g.
+	// CPUUtilization.
+	Metric string `json:"metric"`
+
+	// Statistic is the statistic the alarm evaluates. Defaults to
+	// Average.
+	// +optional
+	Statistic string `json:"statistic,omitempty"`
+
+	// ComparisonOperator is one of the comparison operators CloudWatch
+	// accepts, e.g. GreaterThanThreshold.
+	ComparisonOperator string `json:"comparisonOperator"`
+
+	// Threshold is the value the statistic is compared against.
+	Threshold float64 `json:"threshold"`
+
+	// EvaluationPeriods is the number of periods over which data is
+	// compared to Threshold.
+	EvaluationPeriods int32 `json:"evaluationPeriods"`
+
+	// Period is the alarm's evaluation period, in seconds. Defaults to
+	// the top-level Period when omitted.
+	// +optional
+	Period int32 `json:"period,omitempty"`
+
+	// SNSTopicARN is notified on ALARM and OK transitions, if set.
+	// +optional
+	SNSTopicARN string `json:"snsTopicARN,omitempty"`
+
+	// ForceState, if set, is applied to the alarm via SetAlarmState
+	// instead of being evaluated from real data. Requires
+	// AllowSetAlarmState.
+	// +optional
+	// +kubebuilder:validation:Enum=OK;ALARM;INSUFFICIENT_DATA
+	ForceState string `json:"forceState,omitempty"`
+
+	// ForceStateReason accompanies ForceState.
+	// +optional
+	ForceStateReason string `json:"forceStateReason,omitempty"`
+}
+
+// MetricSpec requests a single CloudWatch metric with an explicit
+// statistic, unit and period.
+type MetricSpec struct {
+	// Name is the CloudWatch metric name, e.g. CPUUtilization.
+	Name string `json:"name"`
+
+	// Stat is the statistic to request: Average, Sum, Minimum, Maximum,
+	// SampleCount, or an extended percentile like p50, p95 or p99.
+	// Defaults to Average.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(Average|Sum|Minimum|Maximum|SampleCount|p(100|[0-9]{1,2}(\.[0-9]+)?))$`
+	Stat string `json:"stat,omitempty"`
+
+	// Unit restricts the datapoints CloudWatch considers to this unit,
+	// e.g. Percent, Bytes or Count/Second.
+	// +optional
+	Unit string `json:"unit,omitempty"`
+
+	// Period overrides the top-level Period for this metric, in seconds.
+	// Must be a multiple of 60 and consistent with CloudWatch's
+	// retention/granularity matrix for how far back it looks.
+	// +optional
+	Period int32 `json:"period,omitempty"`
+}
+
+// CredentialsMode is the method used to resolve the base AWS credentials
+// before any AssumeRole is layered on top.
+type CredentialsMode string
+
+const (
+	// CredentialsModeStatic uses the access-key-id/secret-access-key pair
+	// from the aws-creds credentials.
+	CredentialsModeStatic CredentialsMode = "static"
+
+	// CredentialsModeEnvironment resolves credentials from the process
+	// environment, e.g. AWS_ACCESS_KEY_ID.
+	CredentialsModeEnvironment CredentialsMode = "environment"
+
+	// CredentialsModeShared resolves credentials from the shared
+	// config/credentials files, optionally using Profile.
+	CredentialsModeShared CredentialsMode = "shared"
+
+	// CredentialsModeWebIdentity resolves credentials from a web identity
+	// token, e.g. the IRSA token EKS projects into the pod.
+	CredentialsModeWebIdentity CredentialsMode = "webIdentity"
+
+	// CredentialsModeEC2InstanceRole resolves credentials from the EC2
+	// instance metadata service.
+	CredentialsModeEC2InstanceRole CredentialsMode = "ec2InstanceRole"
+
+	// CredentialsModeAssumeRole resolves the base chain the same way an
+	// empty CredentialsMode would, relying entirely on AssumeRole to
+	// produce usable credentials.
+	CredentialsModeAssumeRole CredentialsMode = "assumeRole"
+)
+
+// AssumeRoleConfig describes an IAM role the function should assume via STS
+// after its base credentials have resolved.
+type AssumeRoleConfig struct {
+	// RoleARN is the ARN of the role to assume.
+	RoleARN string `json:"assumeRoleARN"`
+
+	// ExternalID is passed to STS AssumeRole when the role's trust policy
+	// requires one.
+	// +optional
+	ExternalID string `json:"externalID,omitempty"`
+
+	// SessionName is the role session name used for the assumed session.
+	// Defaults to "function-rds-metrics" when omitted.
+	// +optional
+	SessionName string `json:"sessionName,omitempty"`
+}
+
+// MetricExpression defines a derived CloudWatch metric, evaluated from the
+// results of the other metric and expression queries in the same
+// GetMetricData call.
+type MetricExpression struct {
+	// ID is the CloudWatch MetricDataQuery ID this expression is published
+	// under, and the key its value is reported under in status. It must be
+	// unique among Metrics and Expressions.
+	ID string `json:"id"`
+
+	// Expression is the math expression to evaluate, referencing other
+	// query IDs, e.g. "m0 + m1" or "m0 / m1 * 100".
+	Expression string `json:"expression"`
+
+	// Label is an optional human-readable label for the expression.
+	// +optional
+	Label string `json:"label,omitempty"`
+}
+
+// Dimension is a single CloudWatch metric dimension name/value pair.
+type Dimension struct {
+	// Name is the dimension name, e.g. DBInstanceIdentifier or
+	// LoadBalancerName.
+	Name string `json:"name"`
+
+	// Value is the dimension value, e.g. the resource's identifier.
+	Value string `json:"value"`
+}